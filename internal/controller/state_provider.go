@@ -0,0 +1,72 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+)
+
+// StateStoreProvider is implemented once per cloud so that ClusterController can provision and
+// reconcile the object storage backing a cluster's gitops and terraform state without a
+// per-cloud switch statement. Registering a StateStoreProvider for a new cloud name is enough
+// to make it available to StateStoreCredentials and StateStoreCreate.
+type StateStoreProvider interface {
+	// EnsureCredentials retrieves or creates the credentials needed to manage a cluster's
+	// state store, creating backing storage along the way where the cloud's API requires it
+	EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error)
+	// EnsureBucket provisions any state store bucket that could not be created as part of
+	// EnsureCredentials. Providers that already create their bucket(s) there can return the
+	// cluster's existing state store details unchanged.
+	EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error)
+	// BucketExists reports whether the cluster's state store bucket has already been
+	// provisioned in the cloud, letting Reconcile skip creation instead of blindly re-running
+	// it against a partially provisioned or already-healthy state store
+	BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error)
+}
+
+// stateStoreProviders is the registry of StateStoreProvider implementations keyed by cloud name
+var stateStoreProviders = map[string]StateStoreProvider{
+	"aws":          AWSStateStoreProvider{},
+	"civo":         CivoStateStoreProvider{},
+	"digitalocean": DigitaloceanStateStoreProvider{},
+	"vultr":        VultrStateStoreProvider{},
+	"azure":        AzureStateStoreProvider{},
+	"google":       GoogleStateStoreProvider{},
+}
+
+// CredentialRotator is implemented by providers whose cloud API supports rotating state store
+// access keys without recreating the underlying bucket. It's a separate, optional interface
+// rather than a required StateStoreProvider method since not every cloud supports it - none of
+// the providers registered in stateStoreProviders do today, since their vendored clients expose no dedicated
+// rotation call; a future provider with one should implement this rather than faking rotation
+// through EnsureCredentials, which can re-provision infrastructure instead of just reading it.
+type CredentialRotator interface {
+	RotateCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, error)
+}
+
+// CredentialReader is implemented by providers that can retrieve a cluster's existing state
+// store credentials without provisioning any backing storage. StateStoreReconcile uses it to
+// re-derive credentials for a bucket it has already confirmed exists, instead of falling back to
+// EnsureCredentials, which for some providers (Vultr's CreateObjectStorage, for one) provisions
+// new infrastructure as a side effect and would defeat the idempotency Reconcile exists for.
+type CredentialReader interface {
+	ReadCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, error)
+}
+
+// RegisterStateStoreProvider makes a StateStoreProvider available under the given cloud name,
+// allowing new clouds to be supported without modifying ClusterController
+func RegisterStateStoreProvider(cloud string, provider StateStoreProvider) {
+	stateStoreProviders[cloud] = provider
+}
+
+// GetStateStoreProvider looks up the StateStoreProvider registered for a cloud
+func GetStateStoreProvider(cloud string) (StateStoreProvider, bool) {
+	provider, ok := stateStoreProviders[cloud]
+	return provider, ok
+}
@@ -0,0 +1,175 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+)
+
+// AWSStateStoreProvider implements StateStoreProvider for AWS S3
+type AWSStateStoreProvider struct{}
+
+func (AWSStateStoreProvider) EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error) {
+	// S3 only allows enabling Object Lock at bucket creation time - PutObjectLockConfiguration
+	// returns InvalidBucketState if called later to turn locking on for an existing bucket - so
+	// the state store bucket has to be created with locking enabled up front whenever it's
+	// been requested, rather than bolted on afterward in applyAWSSecurityOptions.
+	objectLockRequested := clctrl.StateStoreSecurityOptions != nil && clctrl.StateStoreSecurityOptions.ObjectLockRetentionDays > 0
+
+	createStateStoreBucket := clctrl.AwsClient.CreateBucket
+	if objectLockRequested {
+		createStateStoreBucket = clctrl.AwsClient.CreateBucketWithObjectLock
+	}
+
+	kubefirstStateStoreBucket, err := createStateStoreBucket(clctrl.KubefirstStateStoreBucketName)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+	}
+
+	kubefirstArtifactsBucket, err := clctrl.AwsClient.CreateBucket(clctrl.KubefirstArtifactsBucketName)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+	}
+
+	creds := types.StateStoreCredentials{
+		AccessKeyID:     clctrl.AwsAccessKeyID,
+		SecretAccessKey: clctrl.AwsSecretAccessKey,
+	}
+
+	stateStoreBucket := strings.ReplaceAll(*kubefirstStateStoreBucket.Location, "/", "")
+	artifactsBucket := strings.ReplaceAll(*kubefirstArtifactsBucket.Location, "/", "")
+
+	details := types.StateStoreDetails{
+		AWSStateStoreBucket: stateStoreBucket,
+		AWSArtifactsBucket:  artifactsBucket,
+	}
+
+	if clctrl.StateStoreSecurityOptions != nil {
+		applied, err := applyAWSSecurityOptions(clctrl, stateStoreBucket, *clctrl.StateStoreSecurityOptions)
+		if err != nil {
+			return types.StateStoreCredentials{}, types.StateStoreDetails{}, fmt.Errorf("error hardening state store bucket %s: %s", stateStoreBucket, err)
+		}
+		details.SecurityOptionsApplied = applied
+	}
+
+	return creds, details, nil
+}
+
+// ReadCredentials returns the cluster's AWS state store credentials without touching any
+// bucket; they're the account's static IAM access key rather than anything derived per-bucket,
+// so this never needs to call AWS at all
+func (AWSStateStoreProvider) ReadCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, error) {
+	return types.StateStoreCredentials{
+		AccessKeyID:     clctrl.AwsAccessKeyID,
+		SecretAccessKey: clctrl.AwsSecretAccessKey,
+	}, nil
+}
+
+// applyAWSSecurityOptions hardens a state store bucket after creation according to the
+// cluster's requested StateStoreSecurityOptions, returning the settings that were actually
+// applied
+func applyAWSSecurityOptions(clctrl *ClusterController, bucketName string, opts types.StateStoreSecurityOptions) (*types.StateStoreSecurityOptions, error) {
+	applied := types.StateStoreSecurityOptions{}
+
+	if opts.KMSKeyARN != "" {
+		if err := clctrl.AwsClient.PutBucketEncryption(bucketName, opts.KMSKeyARN); err != nil {
+			return nil, fmt.Errorf("error enabling SSE-KMS: %s", err)
+		}
+		applied.KMSKeyARN = opts.KMSKeyARN
+	}
+
+	if opts.EnableVersioning {
+		if err := clctrl.AwsClient.PutBucketVersioning(bucketName); err != nil {
+			return nil, fmt.Errorf("error enabling bucket versioning: %s", err)
+		}
+		applied.EnableVersioning = true
+	}
+
+	if opts.EnablePublicAccessBlock {
+		if err := clctrl.AwsClient.PutPublicAccessBlock(bucketName); err != nil {
+			return nil, fmt.Errorf("error enabling public access block: %s", err)
+		}
+		applied.EnablePublicAccessBlock = true
+	}
+
+	if opts.ObjectLockRetentionDays > 0 {
+		mode := opts.ObjectLockRetentionMode
+		if mode == "" {
+			mode = "GOVERNANCE"
+		}
+
+		if err := clctrl.AwsClient.PutObjectLockConfiguration(bucketName, mode, opts.ObjectLockRetentionDays); err != nil {
+			return nil, fmt.Errorf("error applying object lock retention: %s", err)
+		}
+		applied.ObjectLockRetentionDays = opts.ObjectLockRetentionDays
+		applied.ObjectLockRetentionMode = mode
+	}
+
+	if opts.RestrictToPrincipalARN != "" {
+		if err := clctrl.AwsClient.PutBucketPolicy(bucketName, restrictiveBucketPolicy(bucketName, opts.RestrictToPrincipalARN)); err != nil {
+			return nil, fmt.Errorf("error applying restrictive bucket policy: %s", err)
+		}
+		applied.RestrictToPrincipalARN = opts.RestrictToPrincipalARN
+	}
+
+	return &applied, nil
+}
+
+// restrictiveBucketPolicy builds an IAM policy document limiting all S3 actions on bucketName to
+// a single principal. It explicitly Allows that principal rather than Denying NotPrincipal,
+// since NotPrincipal compares against the caller's literal principal ARN and would lock out an
+// assumed role whose session ARN doesn't match principalARN exactly, including the account root.
+// The Deny statement instead keys off the aws:PrincipalArn condition value, which AWS resolves
+// to the underlying role ARN for an assumed role rather than the per-session ARN, so
+// principalARN should be that role (or user) ARN.
+func restrictiveBucketPolicy(bucketName string, principalARN string) string {
+	return fmt.Sprintf(`{
+	"Version": "2012-10-17",
+	"Statement": [
+		{
+			"Sid": "AllowClusterPrincipal",
+			"Effect": "Allow",
+			"Principal": {"AWS": "%[1]s"},
+			"Action": "s3:*",
+			"Resource": ["arn:aws:s3:::%[2]s", "arn:aws:s3:::%[2]s/*"]
+		},
+		{
+			"Sid": "DenyEveryoneElse",
+			"Effect": "Deny",
+			"Principal": "*",
+			"Action": "s3:*",
+			"Resource": ["arn:aws:s3:::%[2]s", "arn:aws:s3:::%[2]s/*"],
+			"Condition": {"StringNotLike": {"aws:PrincipalArn": "%[1]s"}}
+		}
+	]
+}`, principalARN, bucketName)
+}
+
+func (AWSStateStoreProvider) EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return types.StateStoreDetails{}, err
+	}
+
+	return cl.StateStoreDetails, nil
+}
+
+// BucketExists reports whether the state store bucket has already been provisioned. The
+// vendored AWS runtime client doesn't expose a HeadBucket call today, so this falls back to
+// the locally recorded create check rather than a live lookup.
+func (AWSStateStoreProvider) BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return false, err
+	}
+
+	return cl.StateStoreCreateCheck, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRestrictiveBucketPolicyIsValidJSON(t *testing.T) {
+	policy := restrictiveBucketPolicy("kubefirst-state-store-abc123", "arn:aws:iam::123456789012:role/ClusterRole")
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		t.Fatalf("restrictiveBucketPolicy produced invalid JSON: %s", err)
+	}
+
+	statements, ok := doc["Statement"].([]any)
+	if !ok || len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %+v", doc["Statement"])
+	}
+}
+
+func TestRestrictiveBucketPolicySubstitutesPrincipalAndBucket(t *testing.T) {
+	const bucketName = "kubefirst-state-store-abc123"
+	const principalARN = "arn:aws:iam::123456789012:role/ClusterRole"
+
+	policy := restrictiveBucketPolicy(bucketName, principalARN)
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		t.Fatalf("restrictiveBucketPolicy produced invalid JSON: %s", err)
+	}
+	statements := doc["Statement"].([]any)
+
+	allow := statements[0].(map[string]any)
+	if allow["Effect"] != "Allow" {
+		t.Fatalf("expected the first statement to Allow the cluster principal, got %+v", allow)
+	}
+	principal, ok := allow["Principal"].(map[string]any)
+	if !ok || principal["AWS"] != principalARN {
+		t.Fatalf("expected Principal.AWS to be %q, got %+v", principalARN, allow["Principal"])
+	}
+	allowResources := toStringSlice(t, allow["Resource"])
+	wantResources := []string{"arn:aws:s3:::" + bucketName, "arn:aws:s3:::" + bucketName + "/*"}
+	if allowResources[0] != wantResources[0] || allowResources[1] != wantResources[1] {
+		t.Fatalf("expected Allow Resource %+v, got %+v", wantResources, allowResources)
+	}
+
+	deny := statements[1].(map[string]any)
+	if deny["Effect"] != "Deny" {
+		t.Fatalf("expected the second statement to Deny everyone else, got %+v", deny)
+	}
+	if deny["Principal"] != "*" {
+		t.Fatalf("expected the Deny statement's Principal to be \"*\", got %+v", deny["Principal"])
+	}
+	condition, ok := deny["Condition"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected a Condition on the Deny statement, got %+v", deny["Condition"])
+	}
+	stringNotLike, ok := condition["StringNotLike"].(map[string]any)
+	if !ok || stringNotLike["aws:PrincipalArn"] != principalARN {
+		t.Fatalf("expected Deny condition to key aws:PrincipalArn on %q, got %+v", principalARN, condition)
+	}
+}
+
+func toStringSlice(t *testing.T, v any) []string {
+	t.Helper()
+
+	raw, ok := v.([]any)
+	if !ok {
+		t.Fatalf("expected a JSON array, got %T: %+v", v, v)
+	}
+
+	out := make([]string, len(raw))
+	for i, e := range raw {
+		s, ok := e.(string)
+		if !ok {
+			t.Fatalf("expected array elements to be strings, got %T: %+v", e, e)
+		}
+		out[i] = s
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+	"github.com/kubefirst/kubefirst-api/pkg/azure"
+)
+
+// AzureStateStoreProvider implements StateStoreProvider for Azure Blob Storage
+type AzureStateStoreProvider struct{}
+
+var (
+	azureStorageAccountInvalidChars = regexp.MustCompile(`[^a-z0-9]`)
+	azureContainerInvalidChars      = regexp.MustCompile(`[^a-z0-9-]`)
+	azureContainerRepeatedHyphens   = regexp.MustCompile(`-+`)
+)
+
+// azureStorageAccountName derives a valid Azure storage account name from a cluster's state
+// store bucket name. Storage account names must be 3-24 characters, lowercase letters and
+// digits only, so this strips anything else and bounds the length rather than passing the
+// bucket name through unchecked.
+func azureStorageAccountName(bucketName string) string {
+	name := azureStorageAccountInvalidChars.ReplaceAllString(strings.ToLower(bucketName), "")
+	if len(name) > 24 {
+		name = name[:24]
+	}
+	for len(name) < 3 {
+		name += "0"
+	}
+	return name
+}
+
+// azureContainerName derives a valid Azure blob container name from a cluster's state store
+// bucket name. Container names must be 3-63 characters, lowercase letters, digits and hyphens,
+// and must start and end with a letter or digit.
+func azureContainerName(bucketName string) string {
+	name := azureContainerInvalidChars.ReplaceAllString(strings.ToLower(bucketName), "-")
+	name = azureContainerRepeatedHyphens.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 63 {
+		name = strings.Trim(name[:63], "-")
+	}
+	for len(name) < 3 {
+		name += "0"
+	}
+	return name
+}
+
+func (AzureStateStoreProvider) EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error) {
+	azureConf := azure.NewAzure()
+
+	azureResourceGroup := fmt.Sprintf("%s-state", clctrl.ClusterName)
+	azureStorageAccount := azureStorageAccountName(clctrl.KubefirstStateStoreBucketName)
+	azureContainer := azureContainerName(clctrl.KubefirstStateStoreBucketName)
+
+	_, err := azureConf.CreateStorageAccount(azureResourceGroup, clctrl.CloudRegion, azureStorageAccount)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, fmt.Errorf("error creating azure storage account: %s", err)
+	}
+
+	err = azureConf.CreateBlobContainer(azureResourceGroup, azureStorageAccount, azureContainer)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, fmt.Errorf("error creating azure blob container: %s", err)
+	}
+
+	creds, err := azureConf.GetStorageAccountCredentials(azureResourceGroup, azureStorageAccount)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+	}
+
+	stateStoreData := types.StateStoreCredentials{
+		AccessKeyID:     creds.AccountName,
+		SecretAccessKey: creds.AccessKey,
+		Name:            azureStorageAccount,
+	}
+
+	details := types.StateStoreDetails{
+		AzureStorageAccount: azureStorageAccount,
+		AzureContainer:      azureContainer,
+		AzureResourceGroup:  azureResourceGroup,
+	}
+
+	return stateStoreData, details, nil
+}
+
+// ReadCredentials fetches the storage account's access keys without creating the account or its
+// blob container, for re-deriving credentials against an already-existing state store
+func (AzureStateStoreProvider) ReadCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, error) {
+	azureConf := azure.NewAzure()
+
+	azureResourceGroup := fmt.Sprintf("%s-state", clctrl.ClusterName)
+	azureStorageAccount := azureStorageAccountName(clctrl.KubefirstStateStoreBucketName)
+
+	creds, err := azureConf.GetStorageAccountCredentials(azureResourceGroup, azureStorageAccount)
+	if err != nil {
+		return types.StateStoreCredentials{}, err
+	}
+
+	return types.StateStoreCredentials{
+		AccessKeyID:     creds.AccountName,
+		SecretAccessKey: creds.AccessKey,
+		Name:            azureStorageAccount,
+	}, nil
+}
+
+func (AzureStateStoreProvider) EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return types.StateStoreDetails{}, err
+	}
+
+	return cl.StateStoreDetails, nil
+}
+
+// BucketExists reports whether the storage account backing the cluster's state store has
+// already been provisioned
+func (AzureStateStoreProvider) BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error) {
+	azureConf := azure.NewAzure()
+
+	azureResourceGroup := fmt.Sprintf("%s-state", clctrl.ClusterName)
+	azureStorageAccount := azureStorageAccountName(clctrl.KubefirstStateStoreBucketName)
+
+	return azureConf.StorageAccountExists(azureResourceGroup, azureStorageAccount)
+}
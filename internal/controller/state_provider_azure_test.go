@@ -0,0 +1,129 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAzureStorageAccountName(t *testing.T) {
+	tests := []struct {
+		name       string
+		bucketName string
+		want       string
+	}{
+		{
+			name:       "lowercases and strips invalid characters",
+			bucketName: "My-Bucket_Name123",
+			want:       "mybucketname123",
+		},
+		{
+			name:       "pads names shorter than 3 characters",
+			bucketName: "-_-",
+			want:       "000",
+		},
+		{
+			name:       "pads a single valid character up to the minimum",
+			bucketName: "A",
+			want:       "a00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := azureStorageAccountName(tt.bucketName)
+			if got != tt.want {
+				t.Errorf("azureStorageAccountName(%q) = %q, want %q", tt.bucketName, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("truncates names longer than 24 characters", func(t *testing.T) {
+		bucketName := strings.Repeat("ab1", 20) // 60 valid characters
+		got := azureStorageAccountName(bucketName)
+		if len(got) != 24 {
+			t.Fatalf("expected a 24 character name, got %q (%d chars)", got, len(got))
+		}
+		if got != bucketName[:24] {
+			t.Fatalf("expected a truncation of the sanitized input, got %q", got)
+		}
+	})
+
+	t.Run("result always satisfies Azure storage account naming rules", func(t *testing.T) {
+		valid := regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+		for _, bucketName := range []string{"", "a", "AB", "Kubefirst-State-Store-ABC123", strings.Repeat("z", 100)} {
+			got := azureStorageAccountName(bucketName)
+			if !valid.MatchString(got) {
+				t.Errorf("azureStorageAccountName(%q) = %q, which violates Azure's storage account naming rules", bucketName, got)
+			}
+		}
+	})
+}
+
+func TestAzureContainerName(t *testing.T) {
+	tests := []struct {
+		name       string
+		bucketName string
+		want       string
+	}{
+		{
+			name:       "lowercases and replaces invalid characters with hyphens",
+			bucketName: "My_Bucket.Name",
+			want:       "my-bucket-name",
+		},
+		{
+			name:       "collapses repeated hyphens",
+			bucketName: "foo--bar",
+			want:       "foo-bar",
+		},
+		{
+			name:       "trims leading and trailing hyphens",
+			bucketName: "-bucket-",
+			want:       "bucket",
+		},
+		{
+			name:       "pads names that sanitize down to nothing",
+			bucketName: "___",
+			want:       "000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := azureContainerName(tt.bucketName)
+			if got != tt.want {
+				t.Errorf("azureContainerName(%q) = %q, want %q", tt.bucketName, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("result always satisfies Azure container naming rules", func(t *testing.T) {
+		valid := regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$|^[a-z0-9]{3}$`)
+		inputs := []string{
+			"",
+			"a",
+			"AB",
+			"Kubefirst-State-Store-ABC123",
+			strings.Repeat("ab-", 25), // 75 chars, well past the 63 character limit
+			"___---___",
+		}
+		for _, bucketName := range inputs {
+			got := azureContainerName(bucketName)
+			if len(got) < 3 || len(got) > 63 {
+				t.Errorf("azureContainerName(%q) = %q, length %d outside [3,63]", bucketName, got, len(got))
+			}
+			if strings.HasPrefix(got, "-") || strings.HasSuffix(got, "-") {
+				t.Errorf("azureContainerName(%q) = %q, starts or ends with a hyphen", bucketName, got)
+			}
+			if !valid.MatchString(got) {
+				t.Errorf("azureContainerName(%q) = %q, which violates Azure's container naming rules", bucketName, got)
+			}
+		}
+	})
+}
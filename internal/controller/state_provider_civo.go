@@ -0,0 +1,115 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+	"github.com/kubefirst/runtime/pkg/civo"
+	log "github.com/sirupsen/logrus"
+)
+
+// CivoStateStoreProvider implements StateStoreProvider for Civo object storage
+type CivoStateStoreProvider struct{}
+
+func (CivoStateStoreProvider) EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error) {
+	creds, err := civo.GetAccessCredentials(clctrl.KubefirstStateStoreBucketName, clctrl.CloudRegion)
+	if err != nil {
+		log.Info(err.Error())
+	}
+
+	// Verify all credentials fields are present
+	var civoCredsFailureMessage string
+	switch {
+	case creds.AccessKeyID == "":
+		civoCredsFailureMessage = "when retrieving civo access credentials, AccessKeyID was empty - please retry your cluster creation"
+	case creds.ID == "":
+		civoCredsFailureMessage = "when retrieving civo access credentials, ID was empty - please retry your cluster creation"
+	case creds.Name == "":
+		civoCredsFailureMessage = "when retrieving civo access credentials, Name was empty - please retry your cluster creation"
+	case creds.SecretAccessKeyID == "":
+		civoCredsFailureMessage = "when retrieving civo access credentials, SecretAccessKeyID was empty - please retry your cluster creation"
+	}
+	if civoCredsFailureMessage != "" {
+		// Creds failed to properly parse, so remove them
+		err := civo.DeleteAccessCredentials(clctrl.KubefirstStateStoreBucketName, clctrl.CloudRegion)
+		if err != nil {
+			return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+		}
+
+		// Return error
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, fmt.Errorf(civoCredsFailureMessage)
+	}
+
+	return types.StateStoreCredentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKeyID,
+		Name:            creds.Name,
+		ID:              creds.ID,
+	}, types.StateStoreDetails{}, nil
+}
+
+// ReadCredentials fetches the object store's access credentials without the creation-path
+// validation in EnsureCredentials that deletes them on any empty field, for re-deriving
+// credentials against an already-existing state store without risking a healthy cluster's keys
+func (CivoStateStoreProvider) ReadCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, error) {
+	creds, err := civo.GetAccessCredentials(clctrl.KubefirstStateStoreBucketName, clctrl.CloudRegion)
+	if err != nil {
+		return types.StateStoreCredentials{}, err
+	}
+
+	return types.StateStoreCredentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKeyID,
+		Name:            creds.Name,
+		ID:              creds.ID,
+	}, nil
+}
+
+func (CivoStateStoreProvider) EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return types.StateStoreDetails{}, err
+	}
+
+	accessKeyId := cl.StateStoreCredentials.AccessKeyID
+	log.Infof("access key id %s", accessKeyId)
+
+	bucket, err := civo.CreateStorageBucket(accessKeyId, clctrl.KubefirstStateStoreBucketName, clctrl.CloudRegion)
+	if err != nil {
+		log.Info(err.Error())
+		return types.StateStoreDetails{}, err
+	}
+
+	return types.StateStoreDetails{
+		Name: bucket.Name,
+		ID:   bucket.ID,
+	}, nil
+}
+
+// BucketExists reports whether the state store bucket has already been provisioned. The
+// vendored Civo runtime client doesn't expose a bucket lookup today, so this falls back to
+// the locally recorded create check rather than a live call.
+func (CivoStateStoreProvider) BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return false, err
+	}
+
+	return cl.StateStoreCreateCheck, nil
+}
+
+// CivoStateStoreProvider intentionally does not implement CredentialRotator. The vendored Civo
+// runtime client has no dedicated key-rotation call - civo.GetAccessCredentials just re-fetches
+// the same static keys, so it wouldn't rotate anything. Calling through EnsureCredentials to
+// get that fetch is also unsafe on a timer: EnsureCredentials treats any empty credential field
+// as a failed creation and calls civo.DeleteAccessCredentials, which is the right recovery
+// immediately after a create but would delete a healthy cluster's credentials on a transient
+// read during a periodic sync, and every subsequent tick would then re-fetch empty and delete
+// again.
@@ -0,0 +1,87 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+	"github.com/kubefirst/runtime/pkg/digitalocean"
+)
+
+// DigitaloceanStateStoreProvider implements StateStoreProvider for DigitalOcean Spaces
+type DigitaloceanStateStoreProvider struct{}
+
+func (DigitaloceanStateStoreProvider) EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error) {
+	digitaloceanConf := digitalocean.DigitaloceanConfiguration{
+		Client:  digitalocean.NewDigitalocean(),
+		Context: ctx,
+	}
+
+	creds := digitalocean.DigitaloceanSpacesCredentials{
+		AccessKey:       os.Getenv("DO_SPACES_KEY"),
+		SecretAccessKey: os.Getenv("DO_SPACES_SECRET"),
+		Endpoint:        fmt.Sprintf("%s.digitaloceanspaces.com", "nyc3"),
+	}
+	err := digitaloceanConf.CreateSpaceBucket(creds, clctrl.KubefirstStateStoreBucketName)
+	if err != nil {
+		msg := fmt.Sprintf("error creating spaces bucket %s: %s", clctrl.KubefirstStateStoreBucketName, err)
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, fmt.Errorf(msg)
+	}
+
+	stateStoreData := types.StateStoreCredentials{
+		AccessKeyID:     creds.AccessKey,
+		SecretAccessKey: creds.SecretAccessKey,
+		Name:            clctrl.KubefirstStateStoreBucketName,
+	}
+
+	details := types.StateStoreDetails{
+		Name:     clctrl.KubefirstStateStoreBucketName,
+		Hostname: creds.Endpoint,
+	}
+
+	return stateStoreData, details, nil
+}
+
+// ReadCredentials returns the account's static Spaces access key without touching the bucket,
+// for re-deriving credentials against an already-existing state store
+func (DigitaloceanStateStoreProvider) ReadCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, error) {
+	return types.StateStoreCredentials{
+		AccessKeyID:     os.Getenv("DO_SPACES_KEY"),
+		SecretAccessKey: os.Getenv("DO_SPACES_SECRET"),
+		Name:            clctrl.KubefirstStateStoreBucketName,
+	}, nil
+}
+
+func (DigitaloceanStateStoreProvider) EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return types.StateStoreDetails{}, err
+	}
+
+	return cl.StateStoreDetails, nil
+}
+
+// BucketExists reports whether the Spaces bucket has already been provisioned. The vendored
+// DigitalOcean runtime client doesn't expose a bucket lookup today, so this falls back to the
+// locally recorded create check rather than a live call.
+func (DigitaloceanStateStoreProvider) BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return false, err
+	}
+
+	return cl.StateStoreCreateCheck, nil
+}
+
+// DigitaloceanStateStoreProvider intentionally does not implement CredentialRotator: its
+// DO_SPACES_KEY/DO_SPACES_SECRET pair is a static, account-wide credential sourced from the
+// environment rather than generated per-bucket, and EnsureCredentials re-runs CreateSpaceBucket
+// on every call, so wiring it up as a rotator would re-attempt bucket creation on every sync
+// tick instead of rotating a key.
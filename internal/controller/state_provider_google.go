@@ -0,0 +1,94 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+	"github.com/kubefirst/kubefirst-api/pkg/google"
+)
+
+// GoogleStateStoreProvider implements StateStoreProvider for Google Cloud Storage
+type GoogleStateStoreProvider struct{}
+
+// readGoogleServiceAccountKey returns the contents of the service account key file
+// GOOGLE_APPLICATION_CREDENTIALS points to, or "" if it's unset (application default
+// credentials). The env var itself is a host-local path, not the key, so it's never persisted.
+func readGoogleServiceAccountKey() (string, error) {
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		return "", nil
+	}
+
+	keyBytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading service account key at %s: %s", path, err)
+	}
+
+	return string(keyBytes), nil
+}
+
+func (GoogleStateStoreProvider) EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error) {
+	serviceAccountKey, err := readGoogleServiceAccountKey()
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+	}
+
+	googleConf := google.NewGoogle(clctrl.GoogleProjectID)
+
+	err = googleConf.CreateBucket(clctrl.KubefirstStateStoreBucketName, clctrl.CloudRegion)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+	}
+
+	err = googleConf.CreateBucket(clctrl.KubefirstArtifactsBucketName, clctrl.CloudRegion)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+	}
+
+	creds := types.StateStoreCredentials{
+		ServiceAccountKey: serviceAccountKey,
+	}
+
+	details := types.StateStoreDetails{
+		GCSStateStoreBucket: clctrl.KubefirstStateStoreBucketName,
+		GCSArtifactsBucket:  clctrl.KubefirstArtifactsBucketName,
+		GCPProjectID:        clctrl.GoogleProjectID,
+	}
+
+	return creds, details, nil
+}
+
+// ReadCredentials returns the service account key without touching the bucket, for re-deriving
+// credentials against an already-existing state store
+func (GoogleStateStoreProvider) ReadCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, error) {
+	serviceAccountKey, err := readGoogleServiceAccountKey()
+	if err != nil {
+		return types.StateStoreCredentials{}, err
+	}
+
+	return types.StateStoreCredentials{ServiceAccountKey: serviceAccountKey}, nil
+}
+
+func (GoogleStateStoreProvider) EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return types.StateStoreDetails{}, err
+	}
+
+	return cl.StateStoreDetails, nil
+}
+
+// BucketExists reports whether the state store bucket has already been provisioned
+func (GoogleStateStoreProvider) BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error) {
+	googleConf := google.NewGoogle(clctrl.GoogleProjectID)
+
+	return googleConf.BucketExists(clctrl.KubefirstStateStoreBucketName)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+)
+
+// fakeStateStoreProvider lets tests exercise the registry without any real cloud client
+type fakeStateStoreProvider struct{}
+
+func (fakeStateStoreProvider) EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error) {
+	return types.StateStoreCredentials{Name: "fake"}, types.StateStoreDetails{Name: "fake"}, nil
+}
+
+func (fakeStateStoreProvider) EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error) {
+	return types.StateStoreDetails{Name: "fake"}, nil
+}
+
+func (fakeStateStoreProvider) BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error) {
+	return true, nil
+}
+
+func TestRegisterStateStoreProviderMakesItAvailable(t *testing.T) {
+	RegisterStateStoreProvider("faketest", fakeStateStoreProvider{})
+
+	provider, ok := GetStateStoreProvider("faketest")
+	if !ok {
+		t.Fatal("expected a provider to be registered for faketest")
+	}
+
+	creds, details, err := provider.EnsureCredentials(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error from fake provider: %s", err)
+	}
+	if creds.Name != "fake" || details.Name != "fake" {
+		t.Fatalf("expected the registered fake provider to be the one returned, got creds=%+v details=%+v", creds, details)
+	}
+}
+
+func TestGetStateStoreProviderUnknownCloud(t *testing.T) {
+	if _, ok := GetStateStoreProvider("does-not-exist"); ok {
+		t.Fatal("expected no provider to be registered for an unknown cloud")
+	}
+}
+
+func TestGetStateStoreProviderBuiltins(t *testing.T) {
+	for _, cloud := range []string{"aws", "civo", "digitalocean", "vultr", "azure", "google"} {
+		if _, ok := GetStateStoreProvider(cloud); !ok {
+			t.Errorf("expected a built-in provider registered for %q", cloud)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubefirst/kubefirst-api/internal/types"
+	"github.com/kubefirst/runtime/pkg/vultr"
+	log "github.com/sirupsen/logrus"
+)
+
+// VultrStateStoreProvider implements StateStoreProvider for Vultr object storage
+type VultrStateStoreProvider struct{}
+
+func (VultrStateStoreProvider) EnsureCredentials(ctx context.Context, clctrl *ClusterController) (types.StateStoreCredentials, types.StateStoreDetails, error) {
+	vultrConf := vultr.VultrConfiguration{
+		Client:  vultr.NewVultr(),
+		Context: ctx,
+	}
+
+	objst, err := vultrConf.CreateObjectStorage(clctrl.CloudRegion, clctrl.KubefirstStateStoreBucketName)
+	if err != nil {
+		log.Info(err.Error())
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, err
+	}
+	err = vultrConf.CreateObjectStorageBucket(vultr.VultrBucketCredentials{
+		AccessKey:       objst.S3AccessKey,
+		SecretAccessKey: objst.S3SecretKey,
+		Endpoint:        objst.S3Hostname,
+	}, clctrl.KubefirstStateStoreBucketName)
+	if err != nil {
+		return types.StateStoreCredentials{}, types.StateStoreDetails{}, fmt.Errorf("error creating vultr state storage bucket: %s", err)
+	}
+
+	creds := types.StateStoreCredentials{
+		AccessKeyID:     objst.S3AccessKey,
+		SecretAccessKey: objst.S3SecretKey,
+		Name:            objst.Label,
+		ID:              objst.ID,
+	}
+
+	details := types.StateStoreDetails{
+		Name:     objst.Label,
+		ID:       objst.ID,
+		Hostname: objst.S3Hostname,
+	}
+
+	return creds, details, nil
+}
+
+func (VultrStateStoreProvider) EnsureBucket(ctx context.Context, clctrl *ClusterController) (types.StateStoreDetails, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return types.StateStoreDetails{}, err
+	}
+
+	return cl.StateStoreDetails, nil
+}
+
+// BucketExists reports whether the object storage bucket has already been provisioned. The
+// vendored Vultr runtime client doesn't expose a bucket lookup today, so this falls back to
+// the locally recorded create check rather than a live call.
+func (VultrStateStoreProvider) BucketExists(ctx context.Context, clctrl *ClusterController) (bool, error) {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return false, err
+	}
+
+	return cl.StateStoreCreateCheck, nil
+}
+
+// VultrStateStoreProvider intentionally does not implement CredentialRotator: the vendored
+// Vultr runtime client has no dedicated key-rotation call, and EnsureCredentials provisions a
+// new object-storage subscription on every call, so wiring it up as a rotator would have the
+// sync job re-purchase object storage on every tick instead of rotating a key.
+//
+// For the same reason it also doesn't implement CredentialReader: there's no vendored call that
+// fetches an existing object-storage subscription's credentials without creating a new one, so
+// StateStoreReconcile has no safe way to re-derive Vultr credentials for an already-existing
+// bucket and will surface an error instead of calling EnsureCredentials.
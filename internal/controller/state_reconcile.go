@@ -0,0 +1,99 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// StateStoreReconcile brings a cluster's state store bucket and credentials back in line with
+// what StateStoreCredsCheck/StateStoreCreateCheck expect to be true, and is safe to re-invoke
+// any number of times. Unlike StateStoreCredentials/StateStoreCreate, which only ever run once
+// (gated by those same checks), Reconcile asks the registered StateStoreProvider whether the
+// bucket exists before deciding whether to (re)provision it - so a partial failure that created
+// the bucket but failed to persist the Mongo update doesn't get treated as "not yet
+// provisioned" on the next run. That existence check is a live cloud lookup for Azure and
+// Google; AWS, Civo, DigitalOcean and Vultr have no bucket-lookup call in their vendored
+// clients, so BucketExists falls back to the locally recorded create check for those four,
+// meaning a bucket created but never persisted to Mongo is still not detected as orphaned
+// there. Reconcile also only checks existence, not drift - it won't notice if a bucket's
+// region, versioning or encryption has since diverged from its original spec. When an existing
+// bucket's local credentials are missing, Reconcile only re-derives them through a provider's
+// CredentialReader, never through EnsureCredentials, since EnsureCredentials is allowed to
+// provision storage as a side effect; a provider without CredentialReader surfaces an error
+// instead of silently re-provisioning.
+func (clctrl *ClusterController) StateStoreReconcile() error {
+	cl, err := clctrl.MdbCl.GetCluster(clctrl.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	provider, ok := GetStateStoreProvider(clctrl.CloudProvider)
+	if !ok {
+		return fmt.Errorf("no state store provider registered for cloud provider %s", clctrl.CloudProvider)
+	}
+
+	ctx := context.Background()
+
+	exists, err := provider.BucketExists(ctx, clctrl)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		log.Infof("state store bucket for cluster %s on %s does not exist, provisioning", clctrl.ClusterName, clctrl.CloudProvider)
+
+		if err := clctrl.MdbCl.UpdateCluster(clctrl.ClusterName, "state_store_creds_check", false); err != nil {
+			return err
+		}
+		if err := clctrl.MdbCl.UpdateCluster(clctrl.ClusterName, "state_store_create_check", false); err != nil {
+			return err
+		}
+
+		if err := clctrl.StateStoreCredentials(); err != nil {
+			return err
+		}
+
+		return clctrl.StateStoreCreate()
+	}
+
+	// StateStoreCredsCheck alone is the source of truth for "credentials derived" - it's only
+	// set true once EnsureCredentials has succeeded. An additional check on AccessKeyID/
+	// ServiceAccountKey being blank would misfire for Google's ADC-based auth, where both are
+	// legitimately empty (no GOOGLE_APPLICATION_CREDENTIALS key file), so it isn't used here.
+	if !cl.StateStoreCredsCheck {
+		reader, ok := provider.(CredentialReader)
+		if !ok {
+			return fmt.Errorf("state store bucket for cluster %s on %s exists but local credentials are missing, and its provider has no read-only way to re-derive them without re-provisioning storage - refusing to call EnsureCredentials here to avoid duplicating infrastructure; manual intervention is required", clctrl.ClusterName, clctrl.CloudProvider)
+		}
+
+		log.Infof("state store bucket for cluster %s on %s exists but local credentials are missing, re-deriving", clctrl.ClusterName, clctrl.CloudProvider)
+
+		stateStoreData, err := reader.ReadCredentials(ctx, clctrl)
+		if err != nil {
+			return err
+		}
+
+		if err := clctrl.MdbCl.UpdateCluster(clctrl.ClusterName, "state_store_credentials", stateStoreData); err != nil {
+			return err
+		}
+		if err := clctrl.MdbCl.UpdateCluster(clctrl.ClusterName, "state_store_creds_check", true); err != nil {
+			return err
+		}
+	}
+
+	if !cl.StateStoreCreateCheck {
+		if err := clctrl.MdbCl.UpdateCluster(clctrl.ClusterName, "state_store_create_check", true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
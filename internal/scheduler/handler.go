@@ -0,0 +1,36 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package scheduler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes wires GetStateStoreHealth onto rg, exposing
+// GET /cluster/:cluster_name/statestore/health. The caller is expected to mount rg at the API's
+// "/api/v1" group and to also call Start(ctx) on the same job - registering the route without
+// starting the job leaves it permanently 404ing as "no result found", and starting the job
+// without registering the route makes its results unreachable over the API.
+func (j *StateStoreSyncJob) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/cluster/:cluster_name/statestore/health", j.GetStateStoreHealth)
+}
+
+// GetStateStoreHealth returns the latest StateStoreSyncJob result for the cluster named in the
+// :cluster_name path parameter. Registered by RegisterRoutes.
+func (j *StateStoreSyncJob) GetStateStoreHealth(c *gin.Context) {
+	clusterName := c.Param("cluster_name")
+
+	health, ok := j.Health(clusterName)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"message": "no state store sync result found for cluster " + clusterName})
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
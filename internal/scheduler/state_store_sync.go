@@ -0,0 +1,151 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/kubefirst/kubefirst-api/internal/controller"
+	log "github.com/sirupsen/logrus"
+)
+
+// StateStoreHealth is the latest known health of a cluster's state store, exposed over the API
+type StateStoreHealth struct {
+	Healthy  bool      `json:"healthy"`
+	LastSync time.Time `json:"last_sync"`
+	Message  string    `json:"message,omitempty"`
+}
+
+// StateStoreSyncJob periodically reconciles the state store bucket and credentials for every
+// cluster returned by ClusterControllers. On each tick of a single shared ticker, every
+// cluster's sync is delayed by its own random jitter so a fleet of clusters doesn't all hit
+// cloud APIs at the same instant, and so that running
+// multiple API replicas without leader election just means duplicate, idempotent syncs rather
+// than a thundering herd.
+type StateStoreSyncJob struct {
+	Interval           time.Duration
+	MaxJitter          time.Duration
+	ClusterControllers func() ([]*controller.ClusterController, error)
+
+	mu      sync.RWMutex
+	results map[string]StateStoreHealth
+}
+
+// NewStateStoreSyncJob builds a StateStoreSyncJob that reconciles every cluster returned by
+// clusterControllers roughly every interval, jittered by up to a quarter of the interval
+func NewStateStoreSyncJob(interval time.Duration, clusterControllers func() ([]*controller.ClusterController, error)) *StateStoreSyncJob {
+	return &StateStoreSyncJob{
+		Interval:           interval,
+		MaxJitter:          interval / 4,
+		ClusterControllers: clusterControllers,
+		results:            make(map[string]StateStoreHealth),
+	}
+}
+
+// Start runs the sync loop until ctx is cancelled
+func (j *StateStoreSyncJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.syncAll(ctx)
+			}
+		}
+	}()
+}
+
+// Health returns the last known state store sync result for a cluster
+func (j *StateStoreSyncJob) Health(clusterName string) (StateStoreHealth, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	health, ok := j.results[clusterName]
+	return health, ok
+}
+
+func (j *StateStoreSyncJob) syncAll(ctx context.Context) {
+	clctrls, err := j.ClusterControllers()
+	if err != nil {
+		log.Errorf("error listing clusters for state store sync: %s", err)
+		return
+	}
+
+	for _, clctrl := range clctrls {
+		clctrl := clctrl
+
+		jitter := time.Duration(rand.Int63n(int64(j.MaxJitter) + 1))
+		timer := time.NewTimer(jitter)
+
+		go func() {
+			defer timer.Stop()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				j.sync(clctrl)
+			}
+		}()
+	}
+}
+
+func (j *StateStoreSyncJob) sync(clctrl *controller.ClusterController) {
+	health := StateStoreHealth{LastSync: time.Now()}
+
+	if err := clctrl.StateStoreReconcile(); err != nil {
+		health.Healthy = false
+		health.Message = err.Error()
+		log.Errorf("state store sync failed for cluster %s: %s", clctrl.ClusterName, err)
+		j.recordHealth(clctrl, health)
+		return
+	}
+
+	if provider, ok := controller.GetStateStoreProvider(clctrl.CloudProvider); ok {
+		if rotator, ok := provider.(controller.CredentialRotator); ok {
+			creds, err := rotator.RotateCredentials(context.Background(), clctrl)
+			if err != nil {
+				health.Healthy = false
+				health.Message = fmt.Sprintf("credential rotation failed: %s", err)
+				log.Errorf("state store credential rotation failed for cluster %s: %s", clctrl.ClusterName, err)
+			} else if err := clctrl.MdbCl.UpdateCluster(clctrl.ClusterName, "state_store_credentials", creds); err != nil {
+				health.Healthy = false
+				health.Message = fmt.Sprintf("persisting rotated credentials failed: %s", err)
+				log.Errorf("error persisting rotated state store credentials for cluster %s: %s", clctrl.ClusterName, err)
+			} else {
+				health.Healthy = true
+			}
+		} else {
+			health.Healthy = true
+		}
+	} else {
+		health.Healthy = true
+	}
+
+	j.recordHealth(clctrl, health)
+}
+
+// recordHealth caches health locally for Health/GetStateStoreHealth to serve without a round
+// trip, and persists it to the cluster document's state_store_health field. Health and
+// GetStateStoreHealth serve only this replica's cache; they don't read the persisted field.
+func (j *StateStoreSyncJob) recordHealth(clctrl *controller.ClusterController, health StateStoreHealth) {
+	j.mu.Lock()
+	j.results[clctrl.ClusterName] = health
+	j.mu.Unlock()
+
+	if err := clctrl.MdbCl.UpdateCluster(clctrl.ClusterName, "state_store_health", health); err != nil {
+		log.Errorf("error persisting state store health for cluster %s: %s", clctrl.ClusterName, err)
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package types
+
+// StateStoreCredentials houses cloud credentials used to manage remote
+// state and artifact storage for a cluster
+type StateStoreCredentials struct {
+	AccessKeyID       string `bson:"access_key_id,omitempty" json:"access_key_id,omitempty"`
+	SecretAccessKey   string `bson:"secret_access_key,omitempty" json:"secret_access_key,omitempty"`
+	Name              string `bson:"name,omitempty" json:"name,omitempty"`
+	ID                string `bson:"id,omitempty" json:"id,omitempty"`
+	ServiceAccountKey string `bson:"service_account_key,omitempty" json:"service_account_key,omitempty"`
+}
+
+// StateStoreDetails houses the resulting details of object storage
+// provisioned for a cluster's gitops and terraform state
+type StateStoreDetails struct {
+	AWSStateStoreBucket string `bson:"aws_state_store_bucket,omitempty" json:"aws_state_store_bucket,omitempty"`
+	AWSArtifactsBucket  string `bson:"aws_artifacts_bucket,omitempty" json:"aws_artifacts_bucket,omitempty"`
+	Name                string `bson:"name,omitempty" json:"name,omitempty"`
+	ID                  string `bson:"id,omitempty" json:"id,omitempty"`
+	Hostname            string `bson:"hostname,omitempty" json:"hostname,omitempty"`
+
+	AzureStorageAccount string `bson:"azure_storage_account,omitempty" json:"azure_storage_account,omitempty"`
+	AzureContainer      string `bson:"azure_container,omitempty" json:"azure_container,omitempty"`
+	AzureResourceGroup  string `bson:"azure_resource_group,omitempty" json:"azure_resource_group,omitempty"`
+
+	GCSStateStoreBucket string `bson:"gcs_state_store_bucket,omitempty" json:"gcs_state_store_bucket,omitempty"`
+	GCSArtifactsBucket  string `bson:"gcs_artifacts_bucket,omitempty" json:"gcs_artifacts_bucket,omitempty"`
+	GCPProjectID        string `bson:"gcp_project_id,omitempty" json:"gcp_project_id,omitempty"`
+
+	SecurityOptionsApplied *StateStoreSecurityOptions `bson:"security_options_applied,omitempty" json:"security_options_applied,omitempty"`
+}
+
+// StateStoreSecurityOptions controls the hardening applied to a cluster's state store bucket
+// after it's created. Not every provider supports every field - StateStoreProvider
+// implementations apply what they can and leave the rest unset in SecurityOptionsApplied.
+type StateStoreSecurityOptions struct {
+	// KMSKeyARN, when set, enables SSE-KMS on the bucket using the caller-supplied CMK instead
+	// of the provider's default encryption
+	KMSKeyARN string `bson:"kms_key_arn,omitempty" json:"kms_key_arn,omitempty"`
+	// EnableVersioning turns on bucket versioning so state history can be recovered
+	EnableVersioning bool `bson:"enable_versioning,omitempty" json:"enable_versioning,omitempty"`
+	// EnablePublicAccessBlock rejects any public ACL or bucket policy on the bucket
+	EnablePublicAccessBlock bool `bson:"enable_public_access_block,omitempty" json:"enable_public_access_block,omitempty"`
+	// ObjectLockRetentionDays, when greater than zero, applies an object lock retention period
+	// to new object versions, in the mode given by ObjectLockRetentionMode
+	ObjectLockRetentionDays int `bson:"object_lock_retention_days,omitempty" json:"object_lock_retention_days,omitempty"`
+	// ObjectLockRetentionMode selects the object lock mode applied alongside
+	// ObjectLockRetentionDays: GOVERNANCE (default when left blank) allows the retention period
+	// to be shortened or the lock removed by a principal with s3:BypassGovernanceRetention;
+	// COMPLIANCE cannot be loosened or removed by any principal, including the account root,
+	// until the retention period expires, so callers must opt into it explicitly
+	ObjectLockRetentionMode string `bson:"object_lock_retention_mode,omitempty" json:"object_lock_retention_mode,omitempty"`
+	// RestrictToPrincipalARN, when set, applies a bucket policy limiting access to the given
+	// IAM principal instead of whatever the bucket's default policy would allow
+	RestrictToPrincipalARN string `bson:"restrict_to_principal_arn,omitempty" json:"restrict_to_principal_arn,omitempty"`
+}
@@ -0,0 +1,147 @@
+/*
+Copyright (C) 2021-2023, Kubefirst
+
+This program is licensed under MIT.
+See the LICENSE file for more details.
+*/
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+)
+
+// AzureConfiguration stores session data to organize all Azure functions into a single struct
+type AzureConfiguration struct {
+	Context        context.Context
+	SubscriptionID string
+}
+
+// NewAzure instantiates an AzureConfiguration from the ambient environment, mirroring
+// how the other cloud runtime packages build their client configuration
+func NewAzure() AzureConfiguration {
+	return AzureConfiguration{
+		Context:        context.Background(),
+		SubscriptionID: os.Getenv("AZURE_SUBSCRIPTION_ID"),
+	}
+}
+
+// StorageAccountCredentials holds the access key returned after a storage account is provisioned
+type StorageAccountCredentials struct {
+	AccountName string
+	AccessKey   string
+	Endpoint    string
+}
+
+// CreateStorageAccount provisions an Azure Storage Account in the given resource group and region
+// to back gitops and terraform state for a cluster
+func (conf *AzureConfiguration) CreateStorageAccount(resourceGroup string, region string, accountName string) (*armstorage.Account, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure credential: %s", err)
+	}
+
+	client, err := armstorage.NewAccountsClient(conf.SubscriptionID, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure storage account client: %s", err)
+	}
+
+	poller, err := client.BeginCreate(conf.Context, resourceGroup, accountName, armstorage.AccountCreateParameters{
+		Kind:     to.Ptr(armstorage.KindStorageV2),
+		Location: to.Ptr(region),
+		SKU: &armstorage.SKU{
+			Name: to.Ptr(armstorage.SKUNameStandardLRS),
+		},
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure storage account %s: %s", accountName, err)
+	}
+
+	resp, err := poller.PollUntilDone(conf.Context, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for azure storage account %s: %s", accountName, err)
+	}
+
+	return &resp.Account, nil
+}
+
+// CreateBlobContainer provisions a blob container within an existing storage account
+func (conf *AzureConfiguration) CreateBlobContainer(resourceGroup string, accountName string, containerName string) error {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return fmt.Errorf("error creating azure credential: %s", err)
+	}
+
+	client, err := armstorage.NewBlobContainersClient(conf.SubscriptionID, cred, nil)
+	if err != nil {
+		return fmt.Errorf("error creating azure blob container client: %s", err)
+	}
+
+	_, err = client.Create(conf.Context, resourceGroup, accountName, containerName, armstorage.BlobContainer{}, nil)
+	if err != nil {
+		return fmt.Errorf("error creating azure blob container %s: %s", containerName, err)
+	}
+
+	return nil
+}
+
+// StorageAccountExists reports whether a storage account has already been provisioned in the
+// given resource group, so callers can make provisioning idempotent
+func (conf *AzureConfiguration) StorageAccountExists(resourceGroup string, accountName string) (bool, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating azure credential: %s", err)
+	}
+
+	client, err := armstorage.NewAccountsClient(conf.SubscriptionID, cred, nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating azure storage account client: %s", err)
+	}
+
+	_, err = client.GetProperties(conf.Context, resourceGroup, accountName, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking azure storage account %s: %s", accountName, err)
+	}
+
+	return true, nil
+}
+
+// GetStorageAccountCredentials retrieves the primary access key for a storage account so it can
+// be used to authenticate terraform's azurerm backend
+func (conf *AzureConfiguration) GetStorageAccountCredentials(resourceGroup string, accountName string) (StorageAccountCredentials, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return StorageAccountCredentials{}, fmt.Errorf("error creating azure credential: %s", err)
+	}
+
+	client, err := armstorage.NewAccountsClient(conf.SubscriptionID, cred, nil)
+	if err != nil {
+		return StorageAccountCredentials{}, fmt.Errorf("error creating azure storage account client: %s", err)
+	}
+
+	keys, err := client.ListKeys(conf.Context, resourceGroup, accountName, nil)
+	if err != nil {
+		return StorageAccountCredentials{}, fmt.Errorf("error listing azure storage account keys for %s: %s", accountName, err)
+	}
+	if len(keys.Keys) == 0 || keys.Keys[0].Value == nil {
+		return StorageAccountCredentials{}, fmt.Errorf("no access keys returned for azure storage account %s", accountName)
+	}
+
+	return StorageAccountCredentials{
+		AccountName: accountName,
+		AccessKey:   *keys.Keys[0].Value,
+		Endpoint:    fmt.Sprintf("%s.blob.core.windows.net", accountName),
+	}, nil
+}